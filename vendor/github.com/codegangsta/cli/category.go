@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// lexicographicLess compares two strings case-insensitively, used to sort
+// commands and categories by name.
+func lexicographicLess(i, j string) bool {
+	return strings.ToLower(i) < strings.ToLower(j)
+}
+
+// CommandCategory groups together commands that share a Command.Category,
+// for rendering as a single section in help output.
+type CommandCategory struct {
+	Name     string
+	Commands Commands
+}
+
+// CommandCategories is a slice of category names paired with their
+// commands, sorted by (CategoryOrder, Name).
+type CommandCategories []*CommandCategory
+
+func (c CommandCategories) Len() int {
+	return len(c)
+}
+
+func (c CommandCategories) Less(i, j int) bool {
+	oi, oj := c[i].order(), c[j].order()
+	if oi != oj {
+		return oi < oj
+	}
+	return lexicographicLess(c[i].Name, c[j].Name)
+}
+
+func (c CommandCategories) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+// order returns the lowest CategoryOrder among the category's commands, or
+// zero if none set one explicitly.
+func (c *CommandCategory) order() int {
+	order := 0
+	for i, cmd := range c.Commands {
+		if i == 0 || cmd.CategoryOrder < order {
+			order = cmd.CategoryOrder
+		}
+	}
+	return order
+}
+
+// AddCommand appends cmd to the category named category, creating it if it
+// doesn't already exist, and returns the updated slice.
+func (c CommandCategories) AddCommand(category string, cmd Command) CommandCategories {
+	for _, commandCategory := range c {
+		if commandCategory.Name == category {
+			commandCategory.Commands = append(commandCategory.Commands, cmd)
+			return c
+		}
+	}
+	return append(c, &CommandCategory{Name: category, Commands: []Command{cmd}})
+}
+
+// VisibleCommands returns the category's commands that are neither Hidden
+// nor CategoryHidden, sorted by name. This is the single source of truth
+// HelpCategories and CategoryHelpTemplate both use for per-command
+// filtering, so the two can't drift out of sync with each other.
+func (c CommandCategory) VisibleCommands() Commands {
+	visible := make(Commands, 0, len(c.Commands))
+	for _, cmd := range c.Commands {
+		if !cmd.Hidden && !cmd.CategoryHidden {
+			visible = append(visible, cmd)
+		}
+	}
+	sort.Sort(CommandsByName(visible))
+	return visible
+}
+
+// HelpCategories returns the app's command categories sorted by
+// (CategoryOrder, name), with categories that end up with no
+// VisibleCommands (every command in them Hidden or CategoryHidden) dropped
+// entirely. CategoryHelpTemplate, assigned as App.CustomAppHelpTemplate by
+// startApp whenever any subcommand declares a Category, ranges over this to render
+// commands grouped into named sections, the way `git` or `kubectl` organize
+// dozens of subcommands.
+func (a *App) HelpCategories() []CommandCategory {
+	visible := make([]CommandCategory, 0, len(a.categories))
+	for _, category := range a.categories {
+		if len(category.VisibleCommands()) == 0 {
+			continue
+		}
+		visible = append(visible, *category)
+	}
+
+	sort.Slice(visible, func(i, j int) bool {
+		oi, oj := (&visible[i]).order(), (&visible[j]).order()
+		if oi != oj {
+			return oi < oj
+		}
+		return lexicographicLess(visible[i].Name, visible[j].Name)
+	})
+
+	return visible
+}
+
+// CategoryHelpTemplate is the default text/template for rendering app help
+// once any command declares a Category, grouping commands into named
+// sections instead of one flat COMMANDS list. startApp assigns it to
+// App.CustomAppHelpTemplate automatically - see that assignment for when it
+// applies and how a caller-supplied CustomHelpTemplate takes precedence.
+const CategoryHelpTemplate = `NAME:
+   {{.HelpName}} - {{.Usage}}
+
+USAGE:
+   {{.HelpName}} {{if .VisibleFlags}}[global options]{{end}} command [command options] [arguments...]
+{{if .Description}}
+DESCRIPTION:
+   {{.Description}}
+{{end}}
+{{range .HelpCategories}}{{if .Name}}{{.Name}}:{{else}}COMMANDS:{{end}}
+{{range .VisibleCommands}}   {{join .Names ", "}}{{"\t"}}{{.Usage}}
+{{end}}
+{{end}}{{if .VisibleFlags}}
+GLOBAL OPTIONS:
+   {{range .VisibleFlags}}{{.}}
+   {{end}}{{end}}
+`
@@ -25,8 +25,20 @@ type Command struct {
 	ArgsUsage string
 	// The category the command is part of
 	Category string
+	// CategoryOrder controls where this command's category sorts relative
+	// to others (lower sorts first); categories are otherwise ordered by
+	// name. See App.HelpCategories.
+	CategoryOrder int
+	// Boolean to hide this command from help, like Hidden. A category whose
+	// commands are all Hidden or CategoryHidden is left out of help entirely.
+	CategoryHidden bool
 	// The function to call when checking for bash command completions
 	BashComplete BashCompleteFunc
+	// The function to call to compute structured completions (value plus
+	// description) for this command in the `--generate-completion=<shell>`
+	// mode, in place of the default list of subcommand names. See
+	// Command.Complete. The legacy BashComplete path does not call it.
+	CompletionAction func(*Context) []Completion
 	// An action to execute before any sub-subcommands are run, but after the context is ready
 	// If a non-nil error is returned, no sub-subcommands are run
 	Before BeforeFunc
@@ -44,6 +56,17 @@ type Command struct {
 	Subcommands Commands
 	// List of flags to parse
 	Flags []Flag
+	// List of flags whose values are inherited by every descendant
+	// command, regardless of which level declares them. Persistent flag
+	// values set on a parent survive into a nested subcommand's Before,
+	// Action, and After, and remain overridable at each level.
+	PersistentFlags []Flag
+	// Sources consulted, in order, to fill in flags left unset on the
+	// command line. Precedence is CLI, then these sources in list order (the
+	// first one holding a value wins), then the flag's default; an env source
+	// outranks a config file only when it is listed before it. A source added
+	// by InitInputSourceFromFlag is appended last. See FlagInputSource.
+	InputSources []FlagInputSource
 	// Treat all flags as normal arguments if true
 	SkipFlagParsing bool
 	// Skip argument reordering which attempts to move flags before arguments,
@@ -59,6 +82,9 @@ type Command struct {
 	// single-character bool arguements into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool
+	// Boolean to enable suggesting of near-miss subcommand and flag names
+	// ("Did you mean?") when an unknown one is given. Mirrors `App.Suggest`.
+	Suggest bool
 
 	// Full name of command for help, defaults to full command name, including parent commands.
 	HelpName        string
@@ -98,6 +124,12 @@ type Commands []Command
 
 // Run invokes the command given the context, parses ctx.Args() to generate command-specific flags
 func (c Command) Run(ctx *Context) (err error) {
+	// Must come before both the Subcommands early-return below and flag
+	// parsing further down - see checkGenerateCompletion's doc comment.
+	if c.checkGenerateCompletion(ctx) {
+		return nil
+	}
+
 	if len(c.Subcommands) > 0 {
 		return c.startApp(ctx)
 	}
@@ -110,7 +142,13 @@ func (c Command) Run(ctx *Context) (err error) {
 		)
 	}
 
-	set, err := flagSet(c.Name, c.Flags)
+	// Persistent flags declared by an ancestor command are cascaded down
+	// to every descendant's PersistentFlags in startApp, so by the time we
+	// get here they're just ordinary flags on c - resolvable via ctx.String
+	// etc. regardless of which level originally declared them. c.Flags
+	// comes last so a local flag of the same name overrides the inherited
+	// one instead of colliding with it in the flag.FlagSet.
+	set, err := flagSet(c.Name, dedupeFlagsByName(append(c.PersistentFlags, c.Flags...)))
 	if err != nil {
 		return err
 	}
@@ -140,6 +178,12 @@ func (c Command) Run(ctx *Context) (err error) {
 		err = set.Parse(append(regularArgs, flagArgs...))
 	}
 
+	if err == nil {
+		if serr := applyInputSources(c, set); serr != nil {
+			return serr
+		}
+	}
+
 	nerr := normalizeFlags(c.Flags, set)
 	if nerr != nil {
 		fmt.Fprintln(ctx.App.Writer, nerr)
@@ -156,11 +200,16 @@ func (c Command) Run(ctx *Context) (err error) {
 
 	if err != nil {
 		if c.OnUsageError != nil {
-			err := c.OnUsageError(context, err, false)
-			context.App.handleExitCoder(context, err)
-			return err
+			return finalize(context, c.OnUsageError(context, err, false))
 		}
 		fmt.Fprintln(context.App.Writer, "Incorrect Usage:", err.Error())
+		if c.Suggest {
+			if flagName, ok := flagFromError(err); ok {
+				if suggestion := SuggestFlag(c.Flags, flagName, c.HideHelp); suggestion != "" {
+					fmt.Fprintln(context.App.Writer, suggestion)
+				}
+			}
+		}
 		fmt.Fprintln(context.App.Writer)
 		ShowCommandHelp(context, c.Name)
 		return err
@@ -172,15 +221,14 @@ func (c Command) Run(ctx *Context) (err error) {
 
 	if c.After != nil {
 		defer func() {
-			afterErr := c.After(context)
-			if afterErr != nil {
-				context.App.handleExitCoder(context, err)
+			if afterErr := c.After(context); afterErr != nil {
 				if err != nil {
 					err = NewMultiError(err, afterErr)
 				} else {
 					err = afterErr
 				}
 			}
+			err = finalize(context, err)
 		}()
 	}
 
@@ -188,9 +236,24 @@ func (c Command) Run(ctx *Context) (err error) {
 		err = c.Before(context)
 		if err != nil {
 			ShowCommandHelp(context, c.Name)
-			context.App.handleExitCoder(context, err)
+			// With an After set, the deferred closure above still runs on
+			// this return and is the one that calls finalize; calling it
+			// here too would exit the process (via OsExiter) before that
+			// defer ever gets a chance to run c.After.
+			if c.After == nil {
+				return finalize(context, err)
+			}
 			return err
 		}
+
+		// InitInputSourceFromFlag's documented usage loads a source from
+		// inside Before and appends it to context.Command.InputSources,
+		// which happens after the applyInputSources call above already ran.
+		// Apply again now so a source registered that way actually reaches
+		// the flags it's meant to fill in.
+		if serr := applyInputSources(context.Command, set); serr != nil {
+			return serr
+		}
 	}
 
 	if c.Action == nil {
@@ -199,8 +262,8 @@ func (c Command) Run(ctx *Context) (err error) {
 
 	err = HandleAction(c.Action, context)
 
-	if err != nil {
-		context.App.handleExitCoder(context, err)
+	if err != nil && c.After == nil {
+		return finalize(context, err)
 	}
 	return err
 }
@@ -276,6 +339,34 @@ func translateShortOptions(flagArgs Args) []string {
 	return flagArgsSeparated
 }
 
+// dedupeFlagsByName collapses flags down to one entry per primary name,
+// keeping the last occurrence of each. Used to let a command's own Flags
+// override an inherited PersistentFlag of the same name instead of
+// registering it twice, which flag.FlagSet panics on.
+func dedupeFlagsByName(flags []Flag) []Flag {
+	order := make([]string, 0, len(flags))
+	byName := make(map[string]Flag, len(flags))
+	for _, f := range flags {
+		name := flagPrimaryName(f)
+		if _, ok := byName[name]; !ok {
+			order = append(order, name)
+		}
+		byName[name] = f
+	}
+
+	deduped := make([]Flag, len(order))
+	for i, name := range order {
+		deduped[i] = byName[name]
+	}
+	return deduped
+}
+
+// flagPrimaryName returns the first name in a flag's (possibly
+// comma-separated) GetName(), e.g. "log-level, l" -> "log-level".
+func flagPrimaryName(f Flag) string {
+	return strings.TrimSpace(strings.SplitN(f.GetName(), ",", 2)[0])
+}
+
 // Names returns the names including short names and aliases.
 func (c Command) Names() []string {
 	names := []string{c.Name}
@@ -314,11 +405,21 @@ func (c Command) startApp(ctx *Context) error {
 
 	// set CommandNotFound
 	app.CommandNotFound = ctx.App.CommandNotFound
+	if app.CommandNotFound == nil && c.Suggest {
+		// No caller-supplied handler: fall back to a "Did you mean?" hint
+		// against this level's non-Hidden subcommands when Suggest is on.
+		app.CommandNotFound = suggestCommandNotFound
+	}
 	app.CustomAppHelpTemplate = c.CustomHelpTemplate
 
 	// set the flags and commands
 	app.Commands = c.Subcommands
-	app.Flags = c.Flags
+	// c.PersistentFlags must be resolvable at this level too (e.g. `app cmd
+	// --flag=x subcmd`), not just cascaded into each child's own
+	// PersistentFlags below - otherwise a persistent flag only parses when
+	// given after the final subcommand name. c.Flags comes last so a local
+	// flag of the same name overrides the inherited one.
+	app.Flags = dedupeFlagsByName(append(append([]Flag{}, c.PersistentFlags...), c.Flags...))
 	app.HideHelp = c.HideHelp
 
 	app.Version = ctx.App.Version
@@ -336,6 +437,20 @@ func (c Command) startApp(ctx *Context) error {
 
 	sort.Sort(app.categories)
 
+	// A caller-supplied CustomHelpTemplate always wins; otherwise, once any
+	// subcommand declares a Category, render help grouped into sections via
+	// CategoryHelpTemplate rather than one flat COMMANDS list.
+	if app.CustomAppHelpTemplate == "" {
+		for _, category := range app.categories {
+			if category.Name != "" {
+				app.CustomAppHelpTemplate = CategoryHelpTemplate
+				break
+			}
+		}
+	}
+
+	app.Suggest = c.Suggest
+
 	// bash completion
 	app.EnableBashCompletion = ctx.App.EnableBashCompletion
 	if c.BashComplete != nil {
@@ -354,6 +469,15 @@ func (c Command) startApp(ctx *Context) error {
 
 	for index, cc := range app.Commands {
 		app.Commands[index].commandNamePath = []string{c.Name, cc.Name}
+		// Cascade this level's persistent flags, plus any inherited from
+		// further up, down to each child so a two-level-deep subcommand
+		// still sees them regardless of which ancestor declared them. The
+		// child's own PersistentFlags come last so it can redeclare one of
+		// the same name to override it, rather than colliding with it.
+		app.Commands[index].PersistentFlags = dedupeFlagsByName(append(
+			append([]Flag{}, c.PersistentFlags...),
+			app.Commands[index].PersistentFlags...,
+		))
 	}
 
 	return app.RunAsSubcommand(ctx)
@@ -363,3 +487,16 @@ func (c Command) startApp(ctx *Context) error {
 func (c Command) VisibleFlags() []Flag {
 	return visibleFlags(c.Flags)
 }
+
+// VisibleSubcommands returns a slice of the Subcommands with Hidden=false
+// and CategoryHidden=false, sorted by name.
+func (c Command) VisibleSubcommands() []Command {
+	visible := make([]Command, 0, len(c.Subcommands))
+	for _, sub := range c.Subcommands {
+		if !sub.Hidden && !sub.CategoryHidden {
+			visible = append(visible, sub)
+		}
+	}
+	sort.Sort(CommandsByName(visible))
+	return visible
+}
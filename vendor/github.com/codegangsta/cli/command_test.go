@@ -0,0 +1,60 @@
+package cli
+
+import "testing"
+
+func TestDedupeFlagsByNameLocalOverridesPersistent(t *testing.T) {
+	persistent := StringFlag{Name: "log-level", Value: "info"}
+	local := StringFlag{Name: "log-level", Value: "debug"}
+
+	deduped := dedupeFlagsByName([]Flag{persistent, local})
+
+	if len(deduped) != 1 {
+		t.Fatalf("want 1 flag after dedupe, got %d", len(deduped))
+	}
+	got, ok := deduped[0].(StringFlag)
+	if !ok || got.Value != "debug" {
+		t.Errorf("want the closer-level flag to win with Value %q, got %+v", "debug", deduped[0])
+	}
+}
+
+func TestDedupeFlagsByNamePreservesOrderOfFirstAppearance(t *testing.T) {
+	flags := []Flag{
+		StringFlag{Name: "a"},
+		StringFlag{Name: "b"},
+		StringFlag{Name: "a"},
+	}
+
+	deduped := dedupeFlagsByName(flags)
+	if len(deduped) != 2 {
+		t.Fatalf("want 2 flags after dedupe, got %d", len(deduped))
+	}
+	if flagPrimaryName(deduped[0]) != "a" || flagPrimaryName(deduped[1]) != "b" {
+		t.Errorf("want order [a, b], got [%s, %s]", flagPrimaryName(deduped[0]), flagPrimaryName(deduped[1]))
+	}
+}
+
+// TestPersistentFlagCascadesRegardlessOfDeclaringLevel checks the merge step
+// of startApp's PersistentFlags cascade over two levels of nesting by
+// applying, level by level, the same dedupeFlagsByName expression startApp
+// uses (it does not run startApp itself): a persistent flag declared two
+// levels up passes through an empty parent, and a child's own declaration
+// of the same name wins over the inherited one.
+func TestPersistentFlagCascadesRegardlessOfDeclaringLevel(t *testing.T) {
+	grandparentPersistent := []Flag{StringFlag{Name: "log-level", Value: "info"}}
+	parentPersistent := []Flag{}
+
+	cascaded := dedupeFlagsByName(append(append([]Flag{}, grandparentPersistent...), parentPersistent...))
+	if len(cascaded) != 1 || flagPrimaryName(cascaded[0]) != "log-level" {
+		t.Fatalf("want grandparent's persistent flag to cascade through an empty parent, got %+v", cascaded)
+	}
+
+	childOverride := []Flag{StringFlag{Name: "log-level", Value: "debug"}}
+	merged := dedupeFlagsByName(append(append([]Flag{}, cascaded...), childOverride...))
+	if len(merged) != 1 {
+		t.Fatalf("want 1 flag after merging the child's override, got %d", len(merged))
+	}
+	got, ok := merged[0].(StringFlag)
+	if !ok || got.Value != "debug" {
+		t.Errorf("want the child's own declaration to win over the inherited one, got %+v", merged[0])
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OsExiter is the function used when finalize needs to terminate the
+// process. Indirected so tests can stub it out instead of actually exiting.
+var OsExiter = os.Exit
+
+// ExitCoder is the interface an error can implement to signal the specific
+// process exit code Before, Action, or After wants, instead of the default
+// of 1.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// Exit wraps msg and code into an ExitCoder. finalize prints msg to
+// ctx.App.ErrWriter (if non-empty) and exits with code, without the usual
+// usage/help output.
+func Exit(msg string, code int) ExitCoder {
+	return &exitError{message: msg, code: code}
+}
+
+type exitError struct {
+	message string
+	code    int
+}
+
+func (e *exitError) Error() string {
+	return e.message
+}
+
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+// MultiError aggregates several errors, e.g. an Action error together with
+// a subsequent After error. Its ExitCode is the max of its children's, so a
+// non-zero code from any of them is never swallowed by a later success.
+type MultiError struct {
+	Errors []error
+}
+
+// NewMultiError builds a MultiError from one or more errors.
+func NewMultiError(errs ...error) MultiError {
+	return MultiError{Errors: errs}
+}
+
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (m MultiError) ExitCode() int {
+	code := 0
+	for _, err := range m.Errors {
+		if coder, ok := err.(ExitCoder); ok {
+			if c := coder.ExitCode(); c > code {
+				code = c
+			}
+		} else if err != nil && code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+// finalize is the single funnel Before, Action, and After all route their
+// returned error through. It prefers ctx.App.ExitErrHandler when set;
+// otherwise, only errors that implement ExitCoder (MultiError included,
+// since it implements ExitCode itself) print their message to
+// ctx.App.ErrWriter and exit via OsExiter. A plain error is handed straight
+// back to the caller untouched - it's not this function's place to decide
+// the process should exit just because some error occurred.
+func finalize(ctx *Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctx.App.ExitErrHandler != nil {
+		ctx.App.ExitErrHandler(ctx, err)
+		return err
+	}
+
+	coder, ok := err.(ExitCoder)
+	if !ok {
+		return err
+	}
+
+	if msg := err.Error(); msg != "" {
+		fmt.Fprintln(ctx.App.ErrWriter, msg)
+	}
+	OsExiter(coder.ExitCode())
+	return err
+}
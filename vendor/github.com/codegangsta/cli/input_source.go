@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FlagInputSource provides flag values from an external source (a config
+// file, environment variables, ...) so flags left unset on the command line
+// can still be populated. Precedence among several sources, and against the
+// command line itself, is controlled by the order they're listed in
+// Command.InputSources: CLI values always win; among InputSources, the
+// first one holding a value for a flag wins.
+type FlagInputSource interface {
+	// Source names the input source, for error messages.
+	Source() string
+	// Value returns the external value for flag name and whether it was
+	// present at all.
+	Value(name string) (string, bool)
+}
+
+// applyInputSources fills in any flag on set that wasn't explicitly passed
+// on the command line from the first of c.InputSources that has a value for
+// it. It sets the flag's Value directly rather than going through
+// set.Set, so the flag.FlagSet's own bookkeeping of "was this explicitly
+// provided" - which ctx.IsSet relies on - still only reflects CLI-provided
+// flags.
+func applyInputSources(c Command, set *flag.FlagSet) error {
+	if len(c.InputSources) == 0 {
+		return nil
+	}
+
+	cliProvided := map[string]bool{}
+	set.Visit(func(f *flag.Flag) {
+		cliProvided[f.Name] = true
+	})
+
+	var rangeErr error
+	set.VisitAll(func(f *flag.Flag) {
+		if rangeErr != nil || cliProvided[f.Name] {
+			return
+		}
+		for _, source := range c.InputSources {
+			value, ok := source.Value(f.Name)
+			if !ok {
+				continue
+			}
+			if err := f.Value.Set(value); err != nil {
+				rangeErr = fmt.Errorf("cli: invalid value for flag %q from %s: %v", f.Name, source.Source(), err)
+			}
+			break
+		}
+	})
+	return rangeErr
+}
+
+// envInputSource resolves flag values from the environment, keyed by the
+// variables each flag's EnvVars() method names.
+type envInputSource struct {
+	flags []Flag
+}
+
+// NewEnvInputSource builds a FlagInputSource that resolves values from the
+// environment variables named by each flag's EnvVars() []string method. A
+// flag without that method is looked up under its name upper-cased, with
+// dots and dashes turned into underscores; an EnvVar struct field is not read.
+func NewEnvInputSource(flags []Flag) FlagInputSource {
+	return &envInputSource{flags: flags}
+}
+
+func (e *envInputSource) Source() string {
+	return "environment"
+}
+
+func (e *envInputSource) Value(name string) (string, bool) {
+	for _, f := range e.flags {
+		if !hasName(f, name) {
+			continue
+		}
+		if envFlag, ok := f.(interface{ EnvVars() []string }); ok {
+			for _, envVar := range envFlag.EnvVars() {
+				if v, ok := os.LookupEnv(envVar); ok {
+					return v, true
+				}
+			}
+			continue
+		}
+		// No explicit EnvVar(s) declared: fall back to the flag's name
+		// upper-cased, e.g. "server.port" -> SERVER_PORT.
+		if v, ok := os.LookupEnv(dottedEnvName(name)); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// mapInputSource resolves flag values from a flattened key/value map,
+// shared by the YAML, JSON, and TOML sources. Dotted keys (server.port) map
+// to flag names the same way.
+type mapInputSource struct {
+	name   string
+	values map[string]string
+}
+
+func (m *mapInputSource) Source() string {
+	return m.name
+}
+
+func (m *mapInputSource) Value(name string) (string, bool) {
+	v, ok := m.values[name]
+	return v, ok
+}
+
+// NewYAMLInputSource reads a YAML config file into a FlagInputSource.
+func NewYAMLInputSource(path string) (FlagInputSource, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return &mapInputSource{name: path, values: flatten("", doc)}, nil
+}
+
+// NewJSONInputSource reads a JSON config file into a FlagInputSource.
+func NewJSONInputSource(path string) (FlagInputSource, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return &mapInputSource{name: path, values: flatten("", doc)}, nil
+}
+
+// NewTOMLInputSource reads a TOML config file into a FlagInputSource.
+func NewTOMLInputSource(path string) (FlagInputSource, error) {
+	var doc map[string]interface{}
+	if _, err := toml.DecodeFile(path, &doc); err != nil {
+		return nil, err
+	}
+	return &mapInputSource{name: path, values: flatten("", doc)}, nil
+}
+
+// flatten turns a nested map (as decoded from YAML/JSON/TOML) into a flat
+// map keyed by dotted path, e.g. {"server": {"port": 8080}} becomes
+// {"server.port": "8080"}.
+func flatten(prefix string, doc map[string]interface{}) map[string]string {
+	out := map[string]string{}
+	for key, value := range doc {
+		full := key
+		if prefix != "" {
+			full = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, fv := range flatten(full, v) {
+				out[k] = fv
+			}
+		case map[interface{}]interface{}:
+			// yaml.v2 decodes nested mappings with interface{} keys.
+			nested := make(map[string]interface{}, len(v))
+			for nk, nv := range v {
+				nested[fmt.Sprint(nk)] = nv
+			}
+			for k, fv := range flatten(full, nested) {
+				out[k] = fv
+			}
+		case float64:
+			// encoding/json decodes every number into float64; %v on one
+			// switches to scientific notation above ~1e6 (e.g. "2e+06" for
+			// 2000000), which numeric flags then fail to parse.
+			out[full] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			out[full] = fmt.Sprintf("%v", v)
+		}
+	}
+	return out
+}
+
+// InitInputSourceFromFlag returns a BeforeFunc that, once the flag named
+// flagName is resolved, loads a FlagInputSource from its value via load and
+// appends it to ctx.Command.InputSources. Typical use is wiring up a
+// `--config` flag:
+//
+//	Before: cli.InitInputSourceFromFlag("config", cli.NewYAMLInputSource)
+func InitInputSourceFromFlag(flagName string, load func(path string) (FlagInputSource, error)) BeforeFunc {
+	return func(ctx *Context) error {
+		path := ctx.String(flagName)
+		if path == "" {
+			return nil
+		}
+		source, err := load(path)
+		if err != nil {
+			return err
+		}
+		ctx.Command.InputSources = append(ctx.Command.InputSources, source)
+		return nil
+	}
+}
+
+// dottedEnvName upper-cases and replaces dots/dashes with underscores, the
+// conventional default EnvVar naming when a flag doesn't declare its own.
+func dottedEnvName(name string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
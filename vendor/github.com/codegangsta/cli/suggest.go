@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SuggestDidYouMeanTemplate is the format string used to render a suggestion.
+// It receives a single %q verb with the suggested name.
+var SuggestDidYouMeanTemplate = "Did you mean %q?"
+
+// SuggestCommandFunc is executed when an unknown command is encountered, and
+// returns the formatted suggestion text to append to the help/usage output.
+// An empty string means no suggestion was found.
+type SuggestCommandFunc func(commands []Command, provided string) string
+
+// SuggestFlagFunc is executed when an unknown flag is encountered, and
+// returns the formatted suggestion text to append to the usage error output.
+type SuggestFlagFunc func(flags []Flag, provided string, hideHelp bool) string
+
+// SuggestCommand is the default SuggestCommandFunc, overridable so users can
+// plug in their own matcher.
+var SuggestCommand SuggestCommandFunc = suggestCommand
+
+// suggestCommandNotFound is installed as App.CommandNotFound in
+// Command.startApp when Suggest is enabled and the caller hasn't already
+// supplied their own handler, printing a "Did you mean?" hint for the
+// offending subcommand name.
+func suggestCommandNotFound(ctx *Context, command string) {
+	fmt.Fprintf(ctx.App.Writer, "%s: '%s' is not a %s command.\n", ctx.App.Name, command, ctx.App.Name)
+	if suggestion := SuggestCommand(ctx.App.Commands, command); suggestion != "" {
+		fmt.Fprintln(ctx.App.Writer, suggestion)
+	}
+}
+
+// SuggestFlag is the default SuggestFlagFunc, overridable so users can plug
+// in their own matcher.
+var SuggestFlag SuggestFlagFunc = suggestFlag
+
+func suggestCommand(commands []Command, provided string) string {
+	var candidate string
+	best := 0
+	for _, command := range commands {
+		if command.Hidden {
+			continue
+		}
+		for _, name := range command.Names() {
+			if d := distance(name, provided); best == 0 || d < best {
+				best = d
+				candidate = name
+			}
+		}
+	}
+
+	return formatSuggestion(candidate, provided, best)
+}
+
+func suggestFlag(flags []Flag, provided string, hideHelp bool) string {
+	var candidate string
+	best := 0
+	provided = strings.TrimLeft(provided, "-")
+
+	for _, flag := range flags {
+		for _, name := range strings.Split(flag.GetName(), ",") {
+			name = strings.TrimSpace(name)
+			if hideHelp && name == "help" {
+				continue
+			}
+			if d := distance(name, provided); best == 0 || d < best {
+				best = d
+				candidate = name
+			}
+		}
+	}
+
+	// Compute the threshold against the same (dash-trimmed) string distance()
+	// was measured against - re-adding "--" here would let best be up to 1
+	// higher before being rejected as "too far to suggest".
+	suggestion := formatSuggestion(candidate, provided, best)
+	if suggestion != "" && candidate != "" {
+		suggestion = fmt.Sprintf(SuggestDidYouMeanTemplate, "--"+candidate)
+	}
+	return suggestion
+}
+
+// formatSuggestion renders the did-you-mean hint for candidate, provided the
+// edit distance from the offending input is within the acceptable threshold.
+func formatSuggestion(candidate, provided string, dist int) string {
+	if candidate == "" || dist > suggestThreshold(provided) {
+		return ""
+	}
+	return fmt.Sprintf(SuggestDidYouMeanTemplate, candidate)
+}
+
+// suggestThreshold bounds how far a candidate may be from the offending
+// token before it's considered too far to be a useful suggestion.
+func suggestThreshold(provided string) int {
+	if t := len(provided) / 3; t > 2 {
+		return t
+	}
+	return 2
+}
+
+// flagFromError extracts the offending flag name from the error returned by
+// flag.FlagSet.Parse, e.g. "flag provided but not defined: -foo".
+func flagFromError(err error) (string, bool) {
+	const marker = "flag provided but not defined: "
+	msg := err.Error()
+	if idx := strings.Index(msg, marker); idx != -1 {
+		return strings.TrimLeft(msg[idx+len(marker):], "-"), true
+	}
+	return "", false
+}
+
+// distance computes the Damerau-Levenshtein edit distance between a and b,
+// counting transpositions of adjacent characters as a single edit.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	la, lb := len(ra), len(rb)
+
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min3(
+				d[i-1][j]+1,
+				d[i][j-1]+1,
+				d[i-1][j-1]+cost,
+			)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min2(d[i][j], d[i-2][j-2]+cost)
+			}
+		}
+	}
+
+	return d[la][lb]
+}
+
+func min2(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func min3(a, b, c int) int {
+	return min2(min2(a, b), c)
+}
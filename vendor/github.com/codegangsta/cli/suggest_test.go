@@ -0,0 +1,54 @@
+package cli
+
+import "testing"
+
+func TestSuggestCommand(t *testing.T) {
+	commands := []Command{
+		{Name: "status"},
+		{Name: "start"},
+		{Name: "stop"},
+	}
+
+	tests := []struct {
+		name     string
+		provided string
+		want     string
+	}{
+		{"near miss suggests closest command", "stats", `Did you mean "status"?`},
+		{"near miss among several candidates", "sta", `Did you mean "start"?`},
+		{"too far to suggest returns no hint", "xyzzyxyzzyxyzzy", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestCommand(commands, tt.provided); got != tt.want {
+				t.Errorf("suggestCommand(%q) = %q, want %q", tt.provided, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestFlag(t *testing.T) {
+	flags := []Flag{
+		StringFlag{Name: "log-level"},
+		BoolFlag{Name: "verbose"},
+	}
+
+	tests := []struct {
+		name     string
+		provided string
+		want     string
+	}{
+		{"near miss suggests closest long flag", "log-leve", `Did you mean "--log-level"?`},
+		{"leading dashes on input don't affect the match", "--verbos", `Did you mean "--verbose"?`},
+		{"too far to suggest returns no hint", "completely-unrelated-option", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestFlag(flags, tt.provided, false); got != tt.want {
+				t.Errorf("suggestFlag(%q) = %q, want %q", tt.provided, got, tt.want)
+			}
+		})
+	}
+}